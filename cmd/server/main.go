@@ -0,0 +1,30 @@
+// Command server starts the code execution engine's task API: it loads
+// the runner registry from runners/ and serves GET /api/runners and
+// POST /api/tasks (see internal/api and internal/runner).
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/bavi404/scalable-code-execution-engine/internal/api"
+	"github.com/bavi404/scalable-code-execution-engine/internal/runner"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	runnersDir := flag.String("runners-dir", "runners", "directory to discover runner manifests from")
+	flag.Parse()
+
+	registry, err := runner.LoadRegistry(*runnersDir)
+	if err != nil {
+		log.Fatalf("server: load runner registry: %v", err)
+	}
+
+	dispatcher := runner.NewDispatcher(nil)
+	server := api.NewServer(registry, dispatcher)
+
+	log.Printf("server: listening on %s, runners: %d", *addr, len(registry.List()))
+	log.Fatal(http.ListenAndServe(*addr, server.Routes()))
+}
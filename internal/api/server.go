@@ -0,0 +1,292 @@
+// Package api implements the HTTP task API. GET /api/runners exposes the
+// runner registry so clients can discover supported languages and their
+// defaults instead of hard-coding them; POST /api/tasks builds and, on
+// successful compilation, runs a submission against one of them.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/bavi404/scalable-code-execution-engine/internal/runner"
+)
+
+// Server wires the runner registry and dispatcher to the HTTP task API.
+type Server struct {
+	registry   *runner.Registry
+	dispatcher *runner.Dispatcher
+	workDir    string
+}
+
+// NewServer returns a Server backed by reg and dispatcher. Task
+// workspaces are created under os.TempDir().
+func NewServer(reg *runner.Registry, dispatcher *runner.Dispatcher) *Server {
+	return &Server{registry: reg, dispatcher: dispatcher, workDir: os.TempDir()}
+}
+
+// Routes returns the server's handlers, ready to pass to http.ListenAndServe.
+func (s *Server) Routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/runners", s.handleListRunners)
+	mux.HandleFunc("/api/tasks", s.handleSubmitTask)
+	return mux
+}
+
+func (s *Server) handleListRunners(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, s.registry.List())
+}
+
+// TaskRequest is the POST /api/tasks body. Files may include a go.mod
+// (and optional go.sum) alongside the source, which WriteWorkspace
+// passes through to the runner's build image unchanged. Limits, if set,
+// tighten the runner's manifest defaults for this task only (see
+// LimitsOverride).
+type TaskRequest struct {
+	Container string            `json:"container"`
+	GoVersion string            `json:"go_version,omitempty"`
+	Mode      string            `json:"mode,omitempty"`
+	Files     map[string]string `json:"files"`
+	Limits    *LimitsOverride   `json:"limits,omitempty"`
+}
+
+// LimitsOverride lets a task request tighter per-stage CPU/memory/time
+// limits than the runner's manifest default. Fields left zero-valued
+// fall back to the runner's default for that field; a field requesting
+// more than the default is clamped down to it instead of being granted —
+// the manifest default is a hard ceiling, not just a suggestion, since
+// this is the only thing standing between a task and unbounded resource
+// use on the host.
+type LimitsOverride struct {
+	BuildTimeoutSeconds int    `json:"build_timeout_seconds,omitempty"`
+	RunTimeoutSeconds   int    `json:"run_timeout_seconds,omitempty"`
+	BuildCPU            string `json:"build_cpu,omitempty"`
+	BuildMemory         string `json:"build_memory,omitempty"`
+	RunCPU              string `json:"run_cpu,omitempty"`
+	RunMemory           string `json:"run_memory,omitempty"`
+}
+
+// mergeLimits returns defaults with any requested field of override
+// applied on top, clamped so it never exceeds the corresponding default:
+// a task may ask for less of a resource than the runner's manifest
+// grants, never more. A requested value that doesn't parse is treated
+// as absent rather than rejecting the task outright.
+func mergeLimits(defaults runner.ResourceLimits, override *LimitsOverride) runner.ResourceLimits {
+	if override == nil {
+		return defaults
+	}
+	merged := defaults
+	if override.BuildTimeoutSeconds != 0 {
+		merged.BuildTimeoutSeconds = clampTimeoutSeconds(override.BuildTimeoutSeconds, defaults.BuildTimeoutSeconds)
+	}
+	if override.RunTimeoutSeconds != 0 {
+		merged.RunTimeoutSeconds = clampTimeoutSeconds(override.RunTimeoutSeconds, defaults.RunTimeoutSeconds)
+	}
+	if override.BuildCPU != "" {
+		merged.BuildCPU = clampCPU(override.BuildCPU, defaults.BuildCPU)
+	}
+	if override.BuildMemory != "" {
+		merged.BuildMemory = clampMemory(override.BuildMemory, defaults.BuildMemory)
+	}
+	if override.RunCPU != "" {
+		merged.RunCPU = clampCPU(override.RunCPU, defaults.RunCPU)
+	}
+	if override.RunMemory != "" {
+		merged.RunMemory = clampMemory(override.RunMemory, defaults.RunMemory)
+	}
+	return merged
+}
+
+// clampTimeoutSeconds returns requested if it's a positive number of
+// seconds no greater than max, else max.
+func clampTimeoutSeconds(requested, max int) int {
+	if requested <= 0 || requested > max {
+		return max
+	}
+	return requested
+}
+
+// clampCPU returns requested (a docker --cpus value, e.g. "0.5") if it
+// parses and is no greater than max, else max.
+func clampCPU(requested, max string) string {
+	rq, err := strconv.ParseFloat(requested, 64)
+	if err != nil || rq <= 0 {
+		return max
+	}
+	mx, err := strconv.ParseFloat(max, 64)
+	if err != nil || rq > mx {
+		return max
+	}
+	return requested
+}
+
+// clampMemory returns requested (a docker --memory value, e.g. "512m")
+// if it parses and is no greater than max, else max.
+func clampMemory(requested, max string) string {
+	rq, err := parseMemoryBytes(requested)
+	if err != nil || rq <= 0 {
+		return max
+	}
+	mx, err := parseMemoryBytes(max)
+	if err != nil || rq > mx {
+		return max
+	}
+	return requested
+}
+
+// parseMemoryBytes parses a docker --memory value: a byte count,
+// optionally suffixed with b/k/m/g (case-insensitive), e.g. "512m".
+func parseMemoryBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty memory value")
+	}
+	unit := int64(1)
+	switch strings.ToLower(s[len(s)-1:]) {
+	case "b":
+		s = s[:len(s)-1]
+	case "k":
+		unit, s = 1024, s[:len(s)-1]
+	case "m":
+		unit, s = 1024*1024, s[:len(s)-1]
+	case "g":
+		unit, s = 1024*1024*1024, s[:len(s)-1]
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory value %q: %w", s, err)
+	}
+	return int64(n * float64(unit)), nil
+}
+
+// TaskResponse is the POST /api/tasks response body. Stdout/Stderr/
+// Benchmarks are only populated once the run stage executes, i.e. when
+// CompileOK is true; Benchmarks is populated instead of Stdout for a
+// "mode": "benchmark" task.
+type TaskResponse struct {
+	CompileOK   bool                     `json:"compile_ok"`
+	CacheHit    bool                     `json:"cache_hit"`
+	Diagnostics string                   `json:"diagnostics,omitempty"`
+	Stdout      string                   `json:"stdout,omitempty"`
+	Stderr      string                   `json:"stderr,omitempty"`
+	Benchmarks  []runner.BenchmarkResult `json:"benchmarks,omitempty"`
+}
+
+func (s *Server) handleSubmitTask(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Container == "" {
+		http.Error(w, `"container" is required`, http.StatusBadRequest)
+		return
+	}
+
+	rn, ok := s.registry.Get(req.Container)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown container %q", req.Container), http.StatusNotFound)
+		return
+	}
+	if !rn.SupportsVersion(req.GoVersion) {
+		http.Error(w, fmt.Sprintf("unsupported go_version %q for %q; available: %s",
+			req.GoVersion, req.Container, strings.Join(rn.Versions, ", ")), http.StatusBadRequest)
+		return
+	}
+	if !rn.SupportsMode(req.Mode) {
+		http.Error(w, fmt.Sprintf("unsupported mode %q for %q; available: %s",
+			req.Mode, req.Container, strings.Join(rn.Modes, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	dir, err := os.MkdirTemp(s.workDir, "cee-task-")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("could not create workspace: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	if err := runner.WriteWorkspace(dir, benchmarkFiles(rn, req.Mode, req.Files)); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	task := &runner.Task{
+		Runner:       rn,
+		GoVersion:    req.GoVersion,
+		Mode:         req.Mode,
+		Limits:       mergeLimits(rn.Limits, req.Limits),
+		WorkspaceDir: dir,
+	}
+
+	buildResult, err := s.dispatcher.Build(task)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("build stage failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if buildResult.Failed {
+		writeJSON(w, http.StatusOK, TaskResponse{
+			CompileOK:   false,
+			CacheHit:    buildResult.CacheHit,
+			Diagnostics: buildResult.Diagnostics,
+		})
+		return
+	}
+
+	runResult, err := s.dispatcher.Run(task, buildResult.SourceHash)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("run stage failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, TaskResponse{
+		CompileOK:   true,
+		CacheHit:    buildResult.CacheHit,
+		Diagnostics: buildResult.Diagnostics,
+		Stdout:      runResult.Stdout,
+		Stderr:      runResult.Stderr,
+		Benchmarks:  runResult.Benchmarks,
+	})
+}
+
+// benchmarkFiles returns files as submitted, except in benchmark mode on a
+// runner that declares a bench_source_template: there, the file named by
+// the runner's (default) source_template is renamed to the benchmark one,
+// so a client can submit a benchmark the same way it would submit a
+// regular program (as source_template) without needing to know the
+// runner's benchmark-specific filename (e.g. bench_test.go, which must
+// end in _test.go for `go test -c` to pick it up).
+func benchmarkFiles(rn *runner.Runner, mode string, files map[string]string) map[string]string {
+	if mode != "benchmark" || rn.BenchSourceTemplate == "" {
+		return files
+	}
+	content, ok := files[rn.SourceTemplate]
+	if !ok {
+		return files
+	}
+	renamed := make(map[string]string, len(files))
+	for name, v := range files {
+		renamed[name] = v
+	}
+	delete(renamed, rn.SourceTemplate)
+	renamed[rn.BenchSourceTemplate] = content
+	return renamed
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,434 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/bavi404/scalable-code-execution-engine/internal/runner"
+)
+
+// fakeCommandRunner stubs out the Docker CLI for tests. It also records
+// which files were present in the mounted /workspace at build time, so
+// tests can assert on what was actually written there before the
+// (deferred) workspace cleanup runs.
+type fakeCommandRunner struct {
+	buildOutput      string
+	runOutput        string
+	workspaceAtBuild []string
+	calls            [][]string
+}
+
+func (f *fakeCommandRunner) Run(args []string) (string, string, error) {
+	f.calls = append(f.calls, args)
+	if len(args) >= 2 && args[1] == "volume" {
+		return "", "", nil
+	}
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == "-v" && strings.HasSuffix(args[i+1], ":/workspace") {
+			src := strings.TrimSuffix(args[i+1], ":/workspace")
+			entries, _ := os.ReadDir(src)
+			for _, e := range entries {
+				f.workspaceAtBuild = append(f.workspaceAtBuild, e.Name())
+			}
+		}
+	}
+	if args[len(args)-1] == "golang-run" {
+		return f.runOutput, "", nil
+	}
+	return f.buildOutput, "", nil
+}
+
+func testServer(t *testing.T, buildOutput string) (*Server, *fakeCommandRunner) {
+	t.Helper()
+	reg := loadTestRegistry(t)
+	fake := &fakeCommandRunner{buildOutput: buildOutput, runOutput: "===RUNTIME===\n"}
+	dispatcher := runner.NewDispatcher(fake)
+	return NewServer(reg, dispatcher), fake
+}
+
+func loadTestRegistry(t *testing.T) *runner.Registry {
+	t.Helper()
+	dir := t.TempDir()
+	runnerDir := dir + "/golang"
+	if err := os.MkdirAll(runnerDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := `{
+		"name": "golang",
+		"language": "go",
+		"build_image": "golang-build",
+		"run_image": "golang-run",
+		"source_template": "main.go",
+		"bench_source_template": "bench_test.go",
+		"versions": ["1.20.14", "1.22.5"],
+		"default_version": "1.22.5",
+		"modes": ["run", "benchmark"],
+		"limits": {"build_timeout_seconds": 30, "run_timeout_seconds": 10, "build_cpu": "1", "build_memory": "512m", "run_cpu": "0.5", "run_memory": "128m"}
+	}`
+	if err := os.WriteFile(runnerDir+"/manifest.json", []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := runner.LoadRegistry(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+func TestHandleListRunners(t *testing.T) {
+	server, _ := testServer(t, "")
+	req := httptest.NewRequest(http.MethodGet, "/api/runners", nil)
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var runners []runner.Runner
+	if err := json.NewDecoder(rec.Body).Decode(&runners); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(runners) != 1 || runners[0].Name != "golang" {
+		t.Fatalf("unexpected runners list: %+v", runners)
+	}
+}
+
+func TestHandleListRunnersRejectsNonGet(t *testing.T) {
+	server, _ := testServer(t, "")
+	req := httptest.NewRequest(http.MethodPost, "/api/runners", nil)
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", rec.Code)
+	}
+}
+
+func TestHandleSubmitTaskRejectsUnknownContainer(t *testing.T) {
+	server, _ := testServer(t, "")
+	body, _ := json.Marshal(TaskRequest{Container: "nonexistent", Files: map[string]string{"main.go": "package main"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
+
+// The request asked specifically for the task payload to be able to
+// carry a go.mod (and optional go.sum) alongside the source; this proves
+// it reaches the build container's workspace rather than being dropped.
+func TestHandleSubmitTaskAcceptsGoModPayload(t *testing.T) {
+	server, fake := testServer(t, "===BUILD_OK===\nabc123\n")
+	body, _ := json.Marshal(TaskRequest{
+		Container: "golang",
+		Files: map[string]string{
+			"main.go": "package main",
+			"go.mod":  "module example.com/m\n\ngo 1.22\n",
+		},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp TaskResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.CompileOK {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	found := false
+	for _, name := range fake.workspaceAtBuild {
+		if name == "go.mod" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("go.mod not written into workspace: %v", fake.workspaceAtBuild)
+	}
+}
+
+// A successful build must be followed by an actual run, and both stages
+// must be pointed at the same named artifacts volume, or the run
+// container has no way to see the binary the build container produced.
+func TestHandleSubmitTaskRunsAfterSuccessfulBuild(t *testing.T) {
+	server, fake := testServer(t, "===BUILD_OK===\nabc123\n")
+	body, _ := json.Marshal(TaskRequest{Container: "golang", Files: map[string]string{"main.go": "package main"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp TaskResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !resp.CompileOK {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	var buildVolume, runVolume string
+	for _, call := range fake.calls {
+		for i := 0; i+1 < len(call); i++ {
+			if call[i] != "-v" || !strings.HasSuffix(call[i+1], ":/artifacts") {
+				continue
+			}
+			if call[len(call)-1] == "golang-build" {
+				buildVolume = call[i+1]
+			} else if call[len(call)-1] == "golang-run" {
+				runVolume = call[i+1]
+			}
+		}
+	}
+	if runVolume == "" {
+		t.Fatal("run stage was never invoked after a successful build")
+	}
+	if buildVolume != runVolume {
+		t.Fatalf("build and run mounted different artifacts volumes: %q vs %q", buildVolume, runVolume)
+	}
+}
+
+// run.sh writes its own ===RUNTIME=== marker to stdout before exec'ing
+// the submission; the API response must not leak that marker into the
+// task's own stdout.
+func TestHandleSubmitTaskStripsRuntimeMarkerFromStdout(t *testing.T) {
+	server, fake := testServer(t, "===BUILD_OK===\nabc123\n")
+	fake.runOutput = "===RUNTIME===\nhi\n"
+	body, _ := json.Marshal(TaskRequest{Container: "golang", Files: map[string]string{"main.go": "package main"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	var resp TaskResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Stdout != "hi" {
+		t.Fatalf("Stdout = %q, want %q", resp.Stdout, "hi")
+	}
+}
+
+// The per-iteration ns/op, B/op, and allocs/op a benchmark task produces
+// must come back as structured data, not buried in the raw stdout blob.
+func TestHandleSubmitTaskSurfacesBenchmarkResults(t *testing.T) {
+	server, fake := testServer(t, "===BUILD_OK===\nabc123\n")
+	fake.runOutput = "===RUNTIME===\n" +
+		"===BENCHMARK_RESULTS===\n" +
+		`{"name":"BenchmarkFoo-8","iterations":1000000,"ns_per_op":1234,"bytes_per_op":16,"allocs_per_op":1}` + "\n"
+	body, _ := json.Marshal(TaskRequest{
+		Container: "golang",
+		Mode:      "benchmark",
+		Files:     map[string]string{"main.go": "package main"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	var resp TaskResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Stdout != "" {
+		t.Fatalf("Stdout = %q, want empty", resp.Stdout)
+	}
+	if len(resp.Benchmarks) != 1 || resp.Benchmarks[0].Name != "BenchmarkFoo-8" || resp.Benchmarks[0].NsPerOp != 1234 {
+		t.Fatalf("unexpected benchmarks: %+v", resp.Benchmarks)
+	}
+}
+
+// A task may tighten the runner's default limits...
+func TestHandleSubmitTaskAppliesLimitsOverride(t *testing.T) {
+	server, fake := testServer(t, "===BUILD_OK===\nabc123\n")
+	body, _ := json.Marshal(TaskRequest{
+		Container: "golang",
+		Files:     map[string]string{"main.go": "package main"},
+		Limits:    &LimitsOverride{RunCPU: "0.1", RunMemory: "64m"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body=%s", rec.Code, rec.Body.String())
+	}
+	var runArgs []string
+	for _, call := range fake.calls {
+		if call[len(call)-1] == "golang-run" {
+			runArgs = call
+		}
+	}
+	if runArgs == nil {
+		t.Fatal("run stage was never invoked")
+	}
+	if !containsPair(runArgs, "--cpus", "0.1") || !containsPair(runArgs, "--memory", "64m") {
+		t.Fatalf("run args did not reflect limits override: %v", runArgs)
+	}
+}
+
+// ...but not loosen them past the manifest default, which is the hard
+// ceiling a sandboxed task is allowed to request.
+func TestHandleSubmitTaskClampsLimitsOverrideToManifestDefault(t *testing.T) {
+	server, fake := testServer(t, "===BUILD_OK===\nabc123\n")
+	body, _ := json.Marshal(TaskRequest{
+		Container: "golang",
+		Files:     map[string]string{"main.go": "package main"},
+		Limits:    &LimitsOverride{RunCPU: "32", RunMemory: "64g", RunTimeoutSeconds: 99999},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body=%s", rec.Code, rec.Body.String())
+	}
+	var runArgs []string
+	for _, call := range fake.calls {
+		if call[len(call)-1] == "golang-run" {
+			runArgs = call
+		}
+	}
+	if runArgs == nil {
+		t.Fatal("run stage was never invoked")
+	}
+	// The manifest in loadTestRegistry sets run_cpu: "0.5", run_memory: "128m", run_timeout_seconds: 10.
+	if !containsPair(runArgs, "--cpus", "0.5") || !containsPair(runArgs, "--memory", "128m") {
+		t.Fatalf("run args were not clamped to the manifest default: %v", runArgs)
+	}
+	if !containsPair(runArgs, "-e", "RUN_TIMEOUT_SECONDS=10") {
+		t.Fatalf("run timeout was not clamped to the manifest default: %v", runArgs)
+	}
+}
+
+func TestMergeLimitsClamping(t *testing.T) {
+	defaults := runner.ResourceLimits{
+		BuildTimeoutSeconds: 30, RunTimeoutSeconds: 10,
+		BuildCPU: "1", BuildMemory: "512m",
+		RunCPU: "0.5", RunMemory: "128m",
+	}
+
+	cases := []struct {
+		name     string
+		override *LimitsOverride
+		want     runner.ResourceLimits
+	}{
+		{"nil override", nil, defaults},
+		{"tighter values honored", &LimitsOverride{RunCPU: "0.1", RunMemory: "64m", RunTimeoutSeconds: 5},
+			runner.ResourceLimits{BuildTimeoutSeconds: 30, RunTimeoutSeconds: 5, BuildCPU: "1", BuildMemory: "512m", RunCPU: "0.1", RunMemory: "64m"}},
+		{"looser values clamped to default", &LimitsOverride{RunCPU: "32", RunMemory: "64g", RunTimeoutSeconds: 99999},
+			defaults},
+		{"unparseable values clamped to default", &LimitsOverride{RunCPU: "not-a-number", RunMemory: "lots"},
+			defaults},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := mergeLimits(defaults, c.override)
+			if got != c.want {
+				t.Fatalf("mergeLimits() = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+}
+
+func containsPair(args []string, flag, value string) bool {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag && args[i+1] == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestHandleSubmitTaskRejectsUnsupportedVersion(t *testing.T) {
+	server, _ := testServer(t, "")
+	body, _ := json.Marshal(TaskRequest{
+		Container: "golang",
+		GoVersion: "1.9.9",
+		Files:     map[string]string{"main.go": "package main"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400, body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleSubmitTaskRejectsUnsupportedMode(t *testing.T) {
+	server, _ := testServer(t, "")
+	body, _ := json.Marshal(TaskRequest{
+		Container: "golang",
+		Mode:      "interactive",
+		Files:     map[string]string{"main.go": "package main"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400, body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+// Submitting a benchmark-mode task with the ordinary source_template
+// filename (the way a client submits a regular program) must reach the
+// build container as the runner's bench_source_template instead, since
+// `go test -c` only picks up files matching its *_test.go convention.
+func TestHandleSubmitTaskRenamesSourceForBenchmarkMode(t *testing.T) {
+	server, fake := testServer(t, "===BUILD_OK===\nabc123\n")
+	body, _ := json.Marshal(TaskRequest{
+		Container: "golang",
+		Mode:      "benchmark",
+		Files:     map[string]string{"main.go": "package main"},
+	})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body=%s", rec.Code, rec.Body.String())
+	}
+	var foundBench, foundMain bool
+	for _, name := range fake.workspaceAtBuild {
+		if name == "bench_test.go" {
+			foundBench = true
+		}
+		if name == "main.go" {
+			foundMain = true
+		}
+	}
+	if !foundBench || foundMain {
+		t.Fatalf("expected main.go renamed to bench_test.go, got workspace: %v", fake.workspaceAtBuild)
+	}
+}
+
+func TestHandleSubmitTaskReportsCompileFailure(t *testing.T) {
+	server, _ := testServer(t, "===COMPILE===\n===COMPILE_FAILED===\n")
+	body, _ := json.Marshal(TaskRequest{Container: "golang", Files: map[string]string{"main.go": "package main"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/tasks", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.Routes().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200, body=%s", rec.Code, rec.Body.String())
+	}
+	var resp TaskResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.CompileOK {
+		t.Fatal("expected CompileOK=false on compile failure")
+	}
+}
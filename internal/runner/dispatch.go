@@ -0,0 +1,268 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Task is a fully-resolved submission: a runner, the version/mode it was
+// asked to run under, the resource limits to enforce, and the workspace
+// directory its files were written into (see WriteWorkspace).
+type Task struct {
+	ID           string
+	Runner       *Runner
+	GoVersion    string
+	Mode         string
+	Limits       ResourceLimits
+	WorkspaceDir string
+}
+
+// CommandRunner executes an external command and captures its output.
+// Dispatcher depends on this interface, not os/exec directly, so tests
+// can substitute a fake instead of shelling out to a real Docker daemon.
+type CommandRunner interface {
+	Run(args []string) (stdout, stderr string, err error)
+}
+
+type execCommandRunner struct{}
+
+func (execCommandRunner) Run(args []string) (string, string, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return stdout.String(), stderr.String(), err
+}
+
+// Dispatcher drives a task's build and run stages as Docker containers.
+// The module cache, build cache, and artifact cache are mounted as named
+// Docker volumes rather than left as the Dockerfiles' bare (anonymous)
+// VOLUME declarations, so repeated tasks against the same runner reuse
+// the same on-disk caches instead of getting a fresh one per container.
+type Dispatcher struct {
+	run CommandRunner
+}
+
+// NewDispatcher returns a Dispatcher that shells out to the real `docker`
+// CLI. Pass a non-nil CommandRunner in tests to avoid requiring Docker.
+func NewDispatcher(r CommandRunner) *Dispatcher {
+	if r == nil {
+		r = execCommandRunner{}
+	}
+	return &Dispatcher{run: r}
+}
+
+func volumeNames(runnerName string) (artifacts, modCache, buildCache string) {
+	return "cee-artifacts-" + runnerName, "cee-modcache-" + runnerName, "cee-buildcache-" + runnerName
+}
+
+// ensureVolumes creates the named volumes a runner's containers use, if
+// they don't already exist. `docker volume create` is a no-op on an
+// existing volume, so this is safe to call on every task.
+func (d *Dispatcher) ensureVolumes(runnerName string) (artifacts, modCache, buildCache string, err error) {
+	artifacts, modCache, buildCache = volumeNames(runnerName)
+	for _, name := range []string{artifacts, modCache, buildCache} {
+		if _, stderr, err := d.run.Run([]string{"docker", "volume", "create", name}); err != nil {
+			return "", "", "", fmt.Errorf("runner: create volume %s: %w (%s)", name, err, stderr)
+		}
+	}
+	return artifacts, modCache, buildCache, nil
+}
+
+func (t *Task) goVersion() string {
+	if t.GoVersion != "" {
+		return t.GoVersion
+	}
+	return t.Runner.DefaultVersion
+}
+
+func (t *Task) mode() string {
+	if t.Mode != "" {
+		return t.Mode
+	}
+	return "run"
+}
+
+// versionEnvArgs returns the `-e` flags that select a toolchain version,
+// named by the runner's own version_env rather than a hardcoded name, so
+// a runner whose build/run scripts expect a differently-named variable
+// still gets it. A runner that doesn't declare version_env has nothing
+// for its scripts to read, so nothing is passed.
+func (t *Task) versionEnvArgs() []string {
+	if t.Runner.VersionEnv == "" {
+		return nil
+	}
+	return []string{"-e", t.Runner.VersionEnv + "=" + t.goVersion()}
+}
+
+// modeEnvArgs is versionEnvArgs' counterpart for mode_env.
+func (t *Task) modeEnvArgs() []string {
+	if t.Runner.ModeEnv == "" {
+		return nil
+	}
+	return []string{"-e", t.Runner.ModeEnv + "=" + t.mode()}
+}
+
+// BuildResult is the outcome of the build stage, parsed from build.sh's
+// === markers (see runners/golang/build.sh).
+type BuildResult struct {
+	SourceHash  string
+	CacheHit    bool
+	Failed      bool
+	Diagnostics string
+}
+
+// Build runs the runner's build image against the task's workspace,
+// including any submitted go.mod/go.sum (see WriteWorkspace), and
+// reports whether compilation succeeded, was skipped via cache hit, or
+// failed (with diagnostics). Module downloads land in a module-cache
+// volume keyed by runner name, persisted across tasks.
+func (d *Dispatcher) Build(task *Task) (*BuildResult, error) {
+	artifacts, modCache, buildCache, err := d.ensureVolumes(task.Runner.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"docker", "run", "--rm",
+		"-v", task.WorkspaceDir + ":/workspace",
+		"-v", artifacts + ":/artifacts",
+		"-v", modCache + ":/go/pkg/mod",
+		"-v", buildCache + ":/go/cache/build",
+	}
+	args = append(args, task.versionEnvArgs()...)
+	args = append(args, task.modeEnvArgs()...)
+	args = append(args,
+		"-e", fmt.Sprintf("BUILD_TIMEOUT_SECONDS=%d", task.Limits.BuildTimeoutSeconds),
+		"--cpus", task.Limits.BuildCPU,
+		"--memory", task.Limits.BuildMemory,
+		task.Runner.BuildImage,
+	)
+
+	stdout, stderr, runErr := d.run.Run(args)
+	result := parseBuildOutput(stdout)
+	result.Diagnostics = stderr
+	if result.Failed {
+		return result, nil
+	}
+	if runErr != nil {
+		return nil, fmt.Errorf("runner: build stage: %w (%s)", runErr, stderr)
+	}
+	return result, nil
+}
+
+// BenchmarkResult is one Benchmark*'s result line, parsed from run.sh's
+// ===BENCHMARK_RESULTS=== NDJSON block.
+type BenchmarkResult struct {
+	Name        string  `json:"name"`
+	Iterations  int64   `json:"iterations"`
+	NsPerOp     float64 `json:"ns_per_op"`
+	BytesPerOp  int64   `json:"bytes_per_op"`
+	AllocsPerOp int64   `json:"allocs_per_op"`
+}
+
+// RunResult is the outcome of the run stage. Stdout is the submission's
+// own output with run.sh's own ===RUNTIME=== marker stripped off and one
+// trailing newline trimmed;
+// Benchmarks is populated instead of Stdout in benchmark mode, parsed
+// from run.sh's ===BENCHMARK_RESULTS=== block.
+type RunResult struct {
+	Stdout     string
+	Stderr     string
+	Benchmarks []BenchmarkResult
+}
+
+// Run runs the runner's run image against the binary Build produced for
+// sourceHash. It mounts the *same* named artifacts volume Build used
+// (keyed only by runner name, not by task), which is what actually lets
+// the run-stage container see a binary the build-stage container
+// produced — the bare `VOLUME ["/artifacts"]` in each Dockerfile only
+// declares the mount point and would otherwise give each container its
+// own anonymous volume.
+func (d *Dispatcher) Run(task *Task, sourceHash string) (*RunResult, error) {
+	artifacts, _, _, err := d.ensureVolumes(task.Runner.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{
+		"docker", "run", "--rm",
+		"-v", artifacts + ":/artifacts",
+		"-e", "SOURCE_HASH=" + sourceHash,
+	}
+	args = append(args, task.modeEnvArgs()...)
+	args = append(args,
+		"-e", fmt.Sprintf("RUN_TIMEOUT_SECONDS=%d", task.Limits.RunTimeoutSeconds),
+		"--cpus", task.Limits.RunCPU,
+		"--memory", task.Limits.RunMemory,
+		task.Runner.RunImage,
+	)
+
+	stdout, stderr, runErr := d.run.Run(args)
+	if runErr != nil {
+		return nil, fmt.Errorf("runner: run stage: %w (%s)", runErr, stderr)
+	}
+	cleanStdout, benchmarks := parseRunOutput(stdout)
+	return &RunResult{Stdout: cleanStdout, Stderr: stderr, Benchmarks: benchmarks}, nil
+}
+
+// parseRunOutput strips run.sh's own ===RUNTIME=== marker line, which
+// precedes every run regardless of mode, and, if run.sh reports a
+// ===BENCHMARK_RESULTS=== block, parses its NDJSON lines into
+// BenchmarkResults instead of passing them through as Stdout. Without a
+// results block, one trailing newline is trimmed from the returned stdout.
+func parseRunOutput(stdout string) (string, []BenchmarkResult) {
+	lines := strings.Split(stdout, "\n")
+	start := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "===RUNTIME===" {
+			start = i + 1
+			break
+		}
+	}
+	lines = lines[start:]
+
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "===BENCHMARK_RESULTS===" {
+			continue
+		}
+		var benchmarks []BenchmarkResult
+		for _, benchLine := range lines[i+1:] {
+			benchLine = strings.TrimSpace(benchLine)
+			if benchLine == "" {
+				continue
+			}
+			var b BenchmarkResult
+			if err := json.Unmarshal([]byte(benchLine), &b); err == nil {
+				benchmarks = append(benchmarks, b)
+			}
+		}
+		return strings.Join(lines[:i], "\n"), benchmarks
+	}
+	return strings.TrimSuffix(strings.Join(lines, "\n"), "\n"), nil
+}
+
+func parseBuildOutput(stdout string) *BuildResult {
+	lines := strings.Split(stdout, "\n")
+	result := &BuildResult{}
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case "===BUILD_CACHE_HIT===":
+			result.CacheHit = true
+			if i+1 < len(lines) {
+				result.SourceHash = strings.TrimSpace(lines[i+1])
+			}
+		case "===BUILD_OK===":
+			if i+1 < len(lines) {
+				result.SourceHash = strings.TrimSpace(lines[i+1])
+			}
+		case "===COMPILE_FAILED===":
+			result.Failed = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,269 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+// fakeCommandRunner records every invocation and replays canned stdout
+// for the build image, so Build can be tested without a Docker daemon.
+type fakeCommandRunner struct {
+	calls       [][]string
+	buildOutput string
+}
+
+func (f *fakeCommandRunner) Run(args []string) (string, string, error) {
+	f.calls = append(f.calls, args)
+	if len(args) >= 2 && args[1] == "volume" {
+		return "", "", nil
+	}
+	return f.buildOutput, "", nil
+}
+
+func testRunner() *Runner {
+	return &Runner{
+		Name:           "golang",
+		BuildImage:     "golang-build",
+		RunImage:       "golang-run",
+		DefaultVersion: "1.22.5",
+		Versions:       []string{"1.22.5"},
+		VersionEnv:     "GO_VERSION",
+		ModeEnv:        "MODE",
+		Limits: ResourceLimits{
+			BuildTimeoutSeconds: 30,
+			RunTimeoutSeconds:   10,
+			BuildCPU:            "1",
+			BuildMemory:         "512m",
+			RunCPU:              "0.5",
+			RunMemory:           "128m",
+		},
+	}
+}
+
+func TestDispatcherBuildUsesNamedModuleAndArtifactVolumes(t *testing.T) {
+	fake := &fakeCommandRunner{buildOutput: "===BUILD_OK===\nabc123\n"}
+	d := NewDispatcher(fake)
+	task := &Task{Runner: testRunner(), WorkspaceDir: "/tmp/ws"}
+
+	result, err := d.Build(task)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if result.Failed || result.CacheHit || result.SourceHash != "abc123" {
+		t.Fatalf("unexpected build result: %+v", result)
+	}
+
+	var buildArgs []string
+	for _, call := range fake.calls {
+		if len(call) > 0 && call[len(call)-1] == "golang-build" {
+			buildArgs = call
+		}
+	}
+	if !containsPair(buildArgs, "-v", "cee-modcache-golang:/go/pkg/mod") {
+		t.Fatalf("build args missing named module cache volume: %v", buildArgs)
+	}
+	if !containsPair(buildArgs, "-v", "cee-artifacts-golang:/artifacts") {
+		t.Fatalf("build args missing named artifact volume: %v", buildArgs)
+	}
+}
+
+// The review flagged that two Dockerfiles each declaring a bare
+// `VOLUME ["/artifacts"]` does NOT share storage between a build
+// container and a run container — only a named volume mounted into both
+// does. Prove the dispatcher actually wires that up.
+func TestDispatcherBuildAndRunShareArtifactsVolume(t *testing.T) {
+	fake := &fakeCommandRunner{buildOutput: "===BUILD_OK===\nabc123\n"}
+	d := NewDispatcher(fake)
+	task := &Task{Runner: testRunner(), WorkspaceDir: "/tmp/ws"}
+
+	result, err := d.Build(task)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, err := d.Run(task, result.SourceHash); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var buildArgs, runArgs []string
+	for _, call := range fake.calls {
+		switch call[len(call)-1] {
+		case "golang-build":
+			buildArgs = call
+		case "golang-run":
+			runArgs = call
+		}
+	}
+	if !containsPair(buildArgs, "-v", "cee-artifacts-golang:/artifacts") {
+		t.Fatalf("build args missing shared artifacts volume: %v", buildArgs)
+	}
+	if !containsPair(runArgs, "-v", "cee-artifacts-golang:/artifacts") {
+		t.Fatalf("run args missing shared artifacts volume: %v", runArgs)
+	}
+}
+
+func TestDispatcherRunUsesTaskLimits(t *testing.T) {
+	fake := &fakeCommandRunner{buildOutput: "===BUILD_OK===\nabc123\n"}
+	d := NewDispatcher(fake)
+	task := &Task{Runner: testRunner(), WorkspaceDir: "/tmp/ws"}
+	task.Limits.RunCPU = "2"
+	task.Limits.RunMemory = "1g"
+
+	if _, err := d.Run(task, "abc123"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	var runArgs []string
+	for _, call := range fake.calls {
+		if call[len(call)-1] == "golang-run" {
+			runArgs = call
+		}
+	}
+	if !containsPair(runArgs, "--cpus", "2") || !containsPair(runArgs, "--memory", "1g") {
+		t.Fatalf("run args did not reflect task limit overrides: %v", runArgs)
+	}
+}
+
+// fakeRunCommandRunner replays distinct canned output per image, so a
+// test can exercise real run-stage stdout instead of only ever stubbing
+// the build stage.
+type fakeRunCommandRunner struct {
+	calls      [][]string
+	buildImage string
+	runOutput  string
+}
+
+func (f *fakeRunCommandRunner) Run(args []string) (string, string, error) {
+	f.calls = append(f.calls, args)
+	if len(args) >= 2 && args[1] == "volume" {
+		return "", "", nil
+	}
+	if args[len(args)-1] == f.buildImage {
+		return "===BUILD_OK===\nabc123\n", "", nil
+	}
+	return f.runOutput, "", nil
+}
+
+// run.sh always writes ===RUNTIME=== to stdout before exec'ing the
+// submitted binary; a plain `mode: "run"` task's own stdout must come
+// back clean, not prefixed with that marker.
+func TestDispatcherRunStripsRuntimeMarkerForPlainMode(t *testing.T) {
+	fake := &fakeRunCommandRunner{buildImage: "golang-build", runOutput: "===RUNTIME===\nhi\n"}
+	d := NewDispatcher(fake)
+	task := &Task{Runner: testRunner(), WorkspaceDir: "/tmp/ws"}
+
+	result, err := d.Run(task, "abc123")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Stdout != "hi" {
+		t.Fatalf("Stdout = %q, want %q", result.Stdout, "hi")
+	}
+	if len(result.Benchmarks) != 0 {
+		t.Fatalf("unexpected benchmarks: %+v", result.Benchmarks)
+	}
+}
+
+func TestDispatcherRunParsesBenchmarkResults(t *testing.T) {
+	runOutput := "===RUNTIME===\n" +
+		"===BENCHMARK_RESULTS===\n" +
+		`{"name":"BenchmarkFoo-8","iterations":1000000,"ns_per_op":1234,"bytes_per_op":16,"allocs_per_op":1}` + "\n"
+	fake := &fakeRunCommandRunner{buildImage: "golang-build", runOutput: runOutput}
+	d := NewDispatcher(fake)
+	task := &Task{Runner: testRunner(), Mode: "benchmark", WorkspaceDir: "/tmp/ws"}
+
+	result, err := d.Run(task, "abc123")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if result.Stdout != "" {
+		t.Fatalf("Stdout = %q, want empty", result.Stdout)
+	}
+	if len(result.Benchmarks) != 1 {
+		t.Fatalf("Benchmarks = %+v, want 1 entry", result.Benchmarks)
+	}
+	got := result.Benchmarks[0]
+	want := BenchmarkResult{Name: "BenchmarkFoo-8", Iterations: 1000000, NsPerOp: 1234, BytesPerOp: 16, AllocsPerOp: 1}
+	if got != want {
+		t.Fatalf("Benchmarks[0] = %+v, want %+v", got, want)
+	}
+}
+
+// A runner whose manifest names its own version_env/mode_env (as a
+// non-Go runner with a differently-named toolchain-selection variable
+// would) must have those names honored, not the golang runner's
+// hardcoded GO_VERSION/MODE.
+func TestDispatcherBuildHonorsCustomVersionAndModeEnvNames(t *testing.T) {
+	fake := &fakeCommandRunner{buildOutput: "===BUILD_OK===\nabc123\n"}
+	d := NewDispatcher(fake)
+	rn := testRunner()
+	rn.VersionEnv = "TOOLCHAIN_VERSION"
+	rn.ModeEnv = "EXEC_MODE"
+	task := &Task{Runner: rn, GoVersion: "1.22.5", Mode: "benchmark", WorkspaceDir: "/tmp/ws"}
+
+	if _, err := d.Build(task); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	var buildArgs []string
+	for _, call := range fake.calls {
+		if call[len(call)-1] == "golang-build" {
+			buildArgs = call
+		}
+	}
+	if !containsPair(buildArgs, "-e", "TOOLCHAIN_VERSION=1.22.5") {
+		t.Fatalf("build args did not use custom version_env: %v", buildArgs)
+	}
+	if !containsPair(buildArgs, "-e", "EXEC_MODE=benchmark") {
+		t.Fatalf("build args did not use custom mode_env: %v", buildArgs)
+	}
+	if containsPair(buildArgs, "-e", "GO_VERSION=1.22.5") || containsPair(buildArgs, "-e", "MODE=benchmark") {
+		t.Fatalf("build args used the hardcoded env names instead of the manifest's: %v", buildArgs)
+	}
+}
+
+// A runner that declares no version_env/mode_env at all (fields are
+// optional) gets no such -e flag, rather than one with an empty name.
+func TestDispatcherBuildOmitsVersionAndModeEnvWhenUnset(t *testing.T) {
+	fake := &fakeCommandRunner{buildOutput: "===BUILD_OK===\nabc123\n"}
+	d := NewDispatcher(fake)
+	rn := testRunner()
+	rn.VersionEnv = ""
+	rn.ModeEnv = ""
+	task := &Task{Runner: rn, WorkspaceDir: "/tmp/ws"}
+
+	if _, err := d.Build(task); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	var buildArgs []string
+	for _, call := range fake.calls {
+		if call[len(call)-1] == "golang-build" {
+			buildArgs = call
+		}
+	}
+	for i, arg := range buildArgs {
+		if arg == "-e" && i+1 < len(buildArgs) && (strings.HasPrefix(buildArgs[i+1], "=") || strings.Contains(buildArgs[i+1], "GO_VERSION") || strings.Contains(buildArgs[i+1], "MODE=")) {
+			t.Fatalf("unexpected version/mode env flag with no version_env/mode_env set: %v", buildArgs)
+		}
+	}
+}
+
+func TestDispatcherBuildCompileFailure(t *testing.T) {
+	fake := &fakeCommandRunner{buildOutput: "===COMPILE===\n===COMPILE_FAILED===\n"}
+	d := NewDispatcher(fake)
+	task := &Task{Runner: testRunner(), WorkspaceDir: "/tmp/ws"}
+
+	result, err := d.Build(task)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if !result.Failed {
+		t.Fatal("expected Failed=true on compile failure")
+	}
+}
+
+func containsPair(args []string, flag, value string) bool {
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] == flag && args[i+1] == value {
+			return true
+		}
+	}
+	return false
+}
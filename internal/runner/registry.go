@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// Registry holds every runner discovered under a runners/ directory,
+// keyed by name. It's safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	runners map[string]*Runner
+}
+
+// LoadRegistry walks dir and registers every immediate subdirectory that
+// contains a manifest.json, matching the discovery contract described in
+// runners/README.md.
+func LoadRegistry(dir string) (*Registry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("runner: read %s: %w", dir, err)
+	}
+
+	reg := &Registry{runners: make(map[string]*Runner)}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		manifestPath := filepath.Join(dir, entry.Name(), "manifest.json")
+		data, err := os.ReadFile(manifestPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("runner: read %s: %w", manifestPath, err)
+		}
+
+		var r Runner
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("runner: parse %s: %w", manifestPath, err)
+		}
+		r.Dir = filepath.Join(dir, entry.Name())
+
+		if r.Name == "" {
+			return nil, fmt.Errorf("runner: %s: manifest has no name", manifestPath)
+		}
+		if _, dup := reg.runners[r.Name]; dup {
+			return nil, fmt.Errorf("runner: duplicate runner name %q", r.Name)
+		}
+		reg.runners[r.Name] = &r
+	}
+
+	return reg, nil
+}
+
+// List returns every registered runner, sorted by name, for GET /api/runners.
+func (reg *Registry) List() []*Runner {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	out := make([]*Runner, 0, len(reg.runners))
+	for _, r := range reg.runners {
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Get looks up a runner by the name a task submission's "container" field
+// specifies.
+func (reg *Registry) Get(name string) (*Runner, bool) {
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+
+	r, ok := reg.runners[name]
+	return r, ok
+}
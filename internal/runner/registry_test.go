@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeManifest(t *testing.T, dir, name, body string) {
+	t.Helper()
+	runnerDir := filepath.Join(dir, name)
+	if err := os.MkdirAll(runnerDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(runnerDir, "manifest.json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRegistryDiscoversManifests(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "golang", `{"name":"golang","language":"go","versions":["1.22.5","1.20.14"],"modes":["run","benchmark"]}`)
+	// A subdirectory with no manifest.json must be ignored, not error.
+	if err := os.MkdirAll(filepath.Join(dir, "scratch"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	reg, err := LoadRegistry(dir)
+	if err != nil {
+		t.Fatalf("LoadRegistry: %v", err)
+	}
+
+	runners := reg.List()
+	if len(runners) != 1 {
+		t.Fatalf("List() = %d runners, want 1", len(runners))
+	}
+	if runners[0].Name != "golang" {
+		t.Fatalf("List()[0].Name = %q, want golang", runners[0].Name)
+	}
+
+	if _, ok := reg.Get("missing"); ok {
+		t.Fatal("Get(missing) = ok, want not found")
+	}
+	r, ok := reg.Get("golang")
+	if !ok {
+		t.Fatal("Get(golang) = not found, want ok")
+	}
+	if !r.SupportsVersion("1.22.5") || r.SupportsVersion("1.9.0") {
+		t.Fatalf("SupportsVersion gave wrong result for %v", r.Versions)
+	}
+	if !r.SupportsMode("benchmark") || r.SupportsMode("interpret") {
+		t.Fatalf("SupportsMode gave wrong result for %v", r.Modes)
+	}
+}
+
+func TestLoadRegistryRejectsDuplicateNames(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "a", `{"name":"dup"}`)
+	writeManifest(t, dir, "b", `{"name":"dup"}`)
+
+	if _, err := LoadRegistry(dir); err == nil {
+		t.Fatal("LoadRegistry with duplicate manifest names: want error, got nil")
+	}
+}
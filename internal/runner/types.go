@@ -0,0 +1,83 @@
+// Package runner defines the Runner type and the registry that discovers
+// runners/ subdirectories, plus the dispatcher that drives their Docker
+// images for a task.
+package runner
+
+// ResourceLimits are the per-stage defaults a runner's manifest declares;
+// a task may override any of them within server-enforced bounds.
+type ResourceLimits struct {
+	BuildTimeoutSeconds int    `json:"build_timeout_seconds"`
+	RunTimeoutSeconds   int    `json:"run_timeout_seconds"`
+	BuildCPU            string `json:"build_cpu"`
+	BuildMemory         string `json:"build_memory"`
+	RunCPU              string `json:"run_cpu"`
+	RunMemory           string `json:"run_memory"`
+}
+
+// BenchmarkConfig describes how a runner exposes benchmark mode, if it
+// supports one.
+type BenchmarkConfig struct {
+	PatternEnv    string `json:"pattern_env"`
+	TargetEnv     string `json:"target_env"`
+	DefaultTarget string `json:"default_target"`
+}
+
+// Runner is the descriptor read from a runners/<name>/manifest.json. It
+// carries everything the dispatcher needs to build and run a submission:
+// which images to use, how sources are laid out, and the limits/versions/
+// modes a task is allowed to request.
+type Runner struct {
+	Name                string           `json:"name"`
+	Language            string           `json:"language"`
+	BuildImage          string           `json:"build_image"`
+	RunImage            string           `json:"run_image"`
+	Workspace           string           `json:"workspace"`
+	SourceTemplate      string           `json:"source_template"`
+	BenchSourceTemplate string           `json:"bench_source_template,omitempty"`
+	BuildCmd            []string         `json:"build_cmd"`
+	RunCmd              []string         `json:"run_cmd"`
+	Versions            []string         `json:"versions,omitempty"`
+	DefaultVersion      string           `json:"default_version,omitempty"`
+	VersionEnv          string           `json:"version_env,omitempty"`
+	Modes               []string         `json:"modes,omitempty"`
+	ModeEnv             string           `json:"mode_env,omitempty"`
+	Benchmark           *BenchmarkConfig `json:"benchmark,omitempty"`
+	Limits              ResourceLimits   `json:"limits"`
+
+	// Dir is the manifest's directory on disk, where the runner's
+	// Dockerfiles live. Not part of the manifest JSON.
+	Dir string `json:"-"`
+}
+
+// SupportsVersion reports whether v is one of the runner's installed
+// toolchain versions. Runners that don't declare a version matrix accept
+// any (empty) version request.
+func (r *Runner) SupportsVersion(v string) bool {
+	if len(r.Versions) == 0 || v == "" {
+		return true
+	}
+	for _, candidate := range r.Versions {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsMode reports whether m is one of the runner's declared modes.
+// Runners that don't declare modes only support the implicit "run" mode.
+func (r *Runner) SupportsMode(m string) bool {
+	if m == "" || m == "run" {
+		return len(r.Modes) == 0 || contains(r.Modes, "run")
+	}
+	return contains(r.Modes, m)
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// allowedWorkspaceFile reports whether name may be placed in a task
+// workspace. go.mod/go.sum let a submission opt into module mode (see
+// build.sh); anything else must be a .go source file.
+func allowedWorkspaceFile(name string) bool {
+	switch name {
+	case "go.mod", "go.sum":
+		return true
+	}
+	return strings.HasSuffix(name, ".go")
+}
+
+// WriteWorkspace materializes a task's submitted files into dir, which
+// becomes the /workspace mount for the build container. Submitted
+// go.mod/go.sum are written alongside the source so build.sh's existing
+// module-mode detection picks them up with no extra plumbing. A go.sum
+// with no accompanying go.mod is rejected rather than silently ignored,
+// since build.sh only ever looks at go.sum once it has already found a
+// go.mod.
+func WriteWorkspace(dir string, files map[string]string) error {
+	if _, hasSum := files["go.sum"]; hasSum {
+		if _, hasMod := files["go.mod"]; !hasMod {
+			return fmt.Errorf("runner: go.sum submitted without a go.mod")
+		}
+	}
+
+	for name, content := range files {
+		if name == "" || strings.ContainsAny(name, "/\\") || name == "." || name == ".." {
+			return fmt.Errorf("runner: invalid workspace file name %q", name)
+		}
+		if !allowedWorkspaceFile(name) {
+			return fmt.Errorf("runner: file %q is not a .go source, go.mod, or go.sum", name)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("runner: write %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteWorkspaceWritesGoModAlongsideSource(t *testing.T) {
+	dir := t.TempDir()
+	err := WriteWorkspace(dir, map[string]string{
+		"main.go": "package main\n",
+		"go.mod":  "module example.com/m\n\ngo 1.22\n",
+		"go.sum":  "",
+	})
+	if err != nil {
+		t.Fatalf("WriteWorkspace: %v", err)
+	}
+
+	for _, name := range []string{"main.go", "go.mod", "go.sum"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Fatalf("expected %s to be written: %v", name, err)
+		}
+	}
+}
+
+func TestWriteWorkspaceRejectsGoSumWithoutGoMod(t *testing.T) {
+	dir := t.TempDir()
+	err := WriteWorkspace(dir, map[string]string{
+		"main.go": "package main\n",
+		"go.sum":  "",
+	})
+	if err == nil {
+		t.Fatal("expected error for go.sum without go.mod, got nil")
+	}
+}
+
+func TestWriteWorkspaceRejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	err := WriteWorkspace(dir, map[string]string{"../escape.go": "package main"})
+	if err == nil {
+		t.Fatal("expected error for path traversal, got nil")
+	}
+}
+
+func TestWriteWorkspaceRejectsDisallowedExtension(t *testing.T) {
+	dir := t.TempDir()
+	err := WriteWorkspace(dir, map[string]string{"payload.sh": "echo hi"})
+	if err == nil {
+		t.Fatal("expected error for non-.go file, got nil")
+	}
+}